@@ -0,0 +1,194 @@
+package main
+
+import "C"
+
+import (
+    "bytes"
+    "compress/flate"
+    "hash/crc32"
+    "io"
+    "os"
+    "sync"
+)
+
+const parallelBlockSize = 1 << 20 // 1 MiB
+
+//export GzipParallel
+func GzipParallel(inputfile string, outputfile string, level int, workers int) int64 {
+    in, err := os.Open(inputfile)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer in.Close()
+
+    out, err := os.OpenFile(outputfile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer out.Close()
+
+    blocks, err := readBlocks(in, parallelBlockSize)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    deflated, err := deflateBlocksParallel(blocks, level, workers)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    if len(deflated) == 0 {
+        // An empty input has no blocks to deflate, but the gzip stream still
+        // needs a terminating BFINAL=1 block to be valid
+        empty, err := deflateBlock(nil, level, true)
+        if err != nil {
+            println(err.Error())
+            return -1
+        }
+        deflated = [][]byte{empty}
+    }
+
+    if err := writeGzipStream(out, blocks, deflated); err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    return getSize(out)
+}
+
+// CRC32 and total length of the original (uncompressed) blocks
+func checksumBlocks(blocks [][]byte) (checksum uint32, total uint32) {
+    for _, block := range blocks {
+        checksum = crc32.Update(checksum, crc32.IEEETable, block)
+        total += uint32(len(block))
+    }
+    return checksum, total
+}
+
+// Split the input into fixed-size blocks, read fully into memory
+func readBlocks(in *os.File, blockSize int) ([][]byte, error) {
+    var blocks [][]byte
+    for {
+        block := make([]byte, blockSize)
+        n, err := io.ReadFull(in, block)
+        if n > 0 {
+            blocks = append(blocks, block[:n])
+        }
+        if err == io.EOF || err == io.ErrUnexpectedEOF {
+            break
+        }
+        if err != nil {
+            return nil, err
+        }
+    }
+    return blocks, nil
+}
+
+// Deflate each block concurrently. Every block but the last is terminated
+// with SyncFlush, which pads it to a byte boundary without setting BFINAL;
+// the last block is terminated with Close, which sets BFINAL so the
+// concatenated stream ends correctly
+func deflateBlocksParallel(blocks [][]byte, level int, workers int) ([][]byte, error) {
+    if workers < 1 {
+        workers = 1
+    }
+
+    results := make([][]byte, len(blocks))
+    errs := make([]error, len(blocks))
+
+    var wg sync.WaitGroup
+    sem := make(chan struct{}, workers)
+
+    for i, block := range blocks {
+        wg.Add(1)
+        sem <- struct{}{}
+        go func(i int, block []byte) {
+            defer wg.Done()
+            defer func() { <-sem }()
+            results[i], errs[i] = deflateBlock(block, level, i == len(blocks)-1)
+        }(i, block)
+    }
+
+    wg.Wait()
+
+    for _, err := range errs {
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    return results, nil
+}
+
+func deflateBlock(block []byte, level int, last bool) ([]byte, error) {
+    var buf bytes.Buffer
+
+    writer, err := flate.NewWriter(&buf, level)
+    if err != nil {
+        return nil, err
+    }
+
+    if _, err := writer.Write(block); err != nil {
+        return nil, err
+    }
+
+    if last {
+        // Close sets BFINAL on its trailing empty stored block, ending the
+        // overall gzip stream
+        if err := writer.Close(); err != nil {
+            return nil, err
+        }
+    } else {
+        // SyncFlush pads to a byte boundary without setting BFINAL, so the
+        // next block's bytes can simply be appended after it
+        if err := writer.Flush(); err != nil {
+            return nil, err
+        }
+    }
+
+    return buf.Bytes(), nil
+}
+
+// Assemble a single gzip header, the deflate blocks in order, and the
+// trailing CRC32/ISIZE footer
+func writeGzipStream(out io.Writer, blocks [][]byte, deflated [][]byte) error {
+    if _, err := out.Write(gzipHeader()); err != nil {
+        return err
+    }
+
+    for _, compressed := range deflated {
+        if _, err := out.Write(compressed); err != nil {
+            return err
+        }
+    }
+
+    checksum, total := checksumBlocks(blocks)
+    footer := make([]byte, 8)
+    littleEndianPutUint32(footer[0:4], checksum)
+    littleEndianPutUint32(footer[4:8], total)
+    _, err := out.Write(footer)
+    return err
+}
+
+// Minimal fixed gzip header (no name/comment/mtime, matching the bytes
+// gzip.Writer would emit with those fields left unset)
+func gzipHeader() []byte {
+    return []byte{
+        0x1f, 0x8b, 8, // magic bytes + deflate method
+        0,          // flags
+        0, 0, 0, 0, // mtime
+        0,   // extra flags
+        255, // OS unknown
+    }
+}
+
+func littleEndianPutUint32(b []byte, v uint32) {
+    b[0] = byte(v)
+    b[1] = byte(v >> 8)
+    b[2] = byte(v >> 16)
+    b[3] = byte(v >> 24)
+}
@@ -0,0 +1,156 @@
+package main
+
+import "C"
+
+import (
+    "bytes"
+    "compress/gzip"
+    "io"
+    "os"
+    "syscall"
+    "unsafe"
+)
+
+//export GzipFD
+func GzipFD(inFD C.int, outFD C.int, level C.int) int64 {
+    in, err := dupFile(int(inFD), "gzipfd-in")
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer in.Close()
+
+    out, err := dupFile(int(outFD), "gzipfd-out")
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer out.Close()
+
+    counter := &countWriter{w: out}
+    writer, err := gzip.NewWriterLevel(counter, int(level))
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    if _, err := io.Copy(writer, in); err != nil {
+        println(err.Error())
+        return -1
+    }
+    if err := writer.Close(); err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    return counter.n
+}
+
+//export GunzipFD
+func GunzipFD(inFD C.int, outFD C.int) int64 {
+    in, err := dupFile(int(inFD), "gunzipfd-in")
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer in.Close()
+
+    out, err := dupFile(int(outFD), "gunzipfd-out")
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer out.Close()
+
+    reader, err := gzip.NewReader(in)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    written, err := io.Copy(out, reader)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    return written
+}
+
+//export GzipBuffer
+func GzipBuffer(inPtr *C.char, inLen C.int, outPtr *C.char, outCap C.int, level C.int) int64 {
+    input := C.GoBytes(unsafe.Pointer(inPtr), inLen)
+
+    var buf bytes.Buffer
+    writer, err := gzip.NewWriterLevel(&buf, int(level))
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    if _, err := writer.Write(input); err != nil {
+        println(err.Error())
+        return -1
+    }
+    if err := writer.Close(); err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    return copyToBuffer(outPtr, outCap, buf.Bytes())
+}
+
+//export GunzipBuffer
+func GunzipBuffer(inPtr *C.char, inLen C.int, outPtr *C.char, outCap C.int) int64 {
+    input := C.GoBytes(unsafe.Pointer(inPtr), inLen)
+
+    reader, err := gzip.NewReader(bytes.NewReader(input))
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    output, err := io.ReadAll(reader)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    return copyToBuffer(outPtr, outCap, output)
+}
+
+// Wrap a duplicate of fd in an *os.File instead of fd itself, so that the
+// os.File finalizer closing its underlying descriptor on GC (see os.NewFile)
+// closes our own private copy rather than a descriptor the caller still owns
+func dupFile(fd int, name string) (*os.File, error) {
+    dup, err := syscall.Dup(fd)
+    if err != nil {
+        return nil, err
+    }
+    return os.NewFile(uintptr(dup), name), nil
+}
+
+// Copy src into the caller-provided buffer, returning the number of bytes
+// written or -1 if src does not fit in dstCap
+func copyToBuffer(dst *C.char, dstCap C.int, src []byte) int64 {
+    if int64(len(src)) > int64(dstCap) {
+        println("output buffer too small")
+        return -1
+    }
+
+    out := unsafe.Slice((*byte)(unsafe.Pointer(dst)), int(dstCap))
+    copy(out, src)
+    return int64(len(src))
+}
+
+// Wraps an io.Writer to track the total number of bytes written, since
+// file descriptors handed to us (pipes, sockets) may not support Stat
+type countWriter struct {
+    w io.Writer
+    n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+    n, err := c.w.Write(p)
+    c.n += int64(n)
+    return n, err
+}
@@ -0,0 +1,196 @@
+package main
+
+import "C"
+
+import (
+    "archive/tar"
+    "compress/bzip2"
+    "compress/gzip"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+//export Bunzip2
+func Bunzip2(inputfile string, outputfile string) int64 {
+    in, err := os.Open(inputfile)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer in.Close()
+
+    out, err := os.OpenFile(outputfile, os.O_WRONLY|os.O_TRUNC, 0644)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer out.Close()
+
+    // Wrap input file in bzip2 reader, there is no writer counterpart in the
+    // standard library so only decompression is supported
+    reader := bzip2.NewReader(in)
+
+    for {
+        written, err := io.Copy(out, reader)
+
+        if err != nil {
+            if err == io.EOF || err == io.ErrUnexpectedEOF {
+                break
+            }
+            println(err.Error())
+            return -1
+        }
+        if written == 0 {
+            break
+        }
+    }
+
+    return getSize(out)
+}
+
+//export TarExtract
+func TarExtract(inputfile string, destdir string) int64 {
+    in, err := os.Open(inputfile)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer in.Close()
+
+    count, err := extractTar(in, destdir)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    return count
+}
+
+//export TarGzExtract
+func TarGzExtract(inputfile string, destdir string) int64 {
+    in, err := os.Open(inputfile)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer in.Close()
+
+    reader, err := gzip.NewReader(in)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer reader.Close()
+
+    count, err := extractTar(reader, destdir)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    return count
+}
+
+//export TarBz2Extract
+func TarBz2Extract(inputfile string, destdir string) int64 {
+    in, err := os.Open(inputfile)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer in.Close()
+
+    count, err := extractTar(bzip2.NewReader(in), destdir)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    return count
+}
+
+// Walk the tar stream from reader and write its entries under destdir,
+// returning the number of entries extracted
+func extractTar(reader io.Reader, destdir string) (int64, error) {
+    tr := tar.NewReader(reader)
+    var count int64
+
+    for {
+        hdr, err := tr.Next()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return count, err
+        }
+
+        target, err := safeJoin(destdir, hdr.Name)
+        if err != nil {
+            return count, err
+        }
+
+        switch hdr.Typeflag {
+        case tar.TypeDir:
+            if err := os.MkdirAll(target, hdr.FileInfo().Mode()); err != nil {
+                return count, err
+            }
+        case tar.TypeSymlink:
+            if filepath.IsAbs(hdr.Linkname) {
+                return count, fmt.Errorf("tar: refusing to extract symlink %q with absolute target %q", hdr.Name, hdr.Linkname)
+            }
+            if resolved := filepath.Join(filepath.Dir(target), hdr.Linkname); !withinDir(destdir, resolved) {
+                return count, fmt.Errorf("tar: refusing to extract symlink %q escaping destination directory", hdr.Name)
+            }
+            if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+                return count, err
+            }
+            if err := os.Symlink(hdr.Linkname, target); err != nil {
+                return count, err
+            }
+        default:
+            if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+                return count, err
+            }
+            out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, hdr.FileInfo().Mode())
+            if err != nil {
+                return count, err
+            }
+            _, err = io.Copy(out, tr)
+            out.Close()
+            if err != nil {
+                return count, err
+            }
+        }
+
+        count++
+    }
+
+    return count, nil
+}
+
+// Join destdir and name, refusing names that would escape destdir via an
+// absolute path or ".." segments (zip-slip/tar-slip)
+func safeJoin(destdir string, name string) (string, error) {
+    if filepath.IsAbs(name) {
+        return "", fmt.Errorf("refusing to extract absolute path %q", name)
+    }
+
+    target := filepath.Join(destdir, name)
+    if !withinDir(destdir, target) {
+        return "", fmt.Errorf("refusing to extract %q outside of destination directory", name)
+    }
+
+    return target, nil
+}
+
+// Report whether path is destdir itself or nested under it
+func withinDir(destdir string, path string) bool {
+    rel, err := filepath.Rel(destdir, path)
+    if err != nil {
+        return false
+    }
+    return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,182 @@
+package main
+
+import "C"
+
+import (
+    "archive/zip"
+    "io"
+    "os"
+    "path/filepath"
+    "unsafe"
+)
+
+//export ZipCreate
+func ZipCreate(outputfile string, inputfiles **C.char, count C.int) int64 {
+    out, err := os.OpenFile(outputfile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer out.Close()
+
+    writer := zip.NewWriter(out)
+
+    for _, inputfile := range charArrayToStrings(inputfiles, count) {
+        if err := addFileToZip(writer, inputfile); err != nil {
+            println(err.Error())
+            return -1
+        }
+    }
+
+    if err := writer.Close(); err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    return getSize(out)
+}
+
+//export ZipExtract
+func ZipExtract(inputfile string, destdir string) int64 {
+    reader, err := zip.OpenReader(inputfile)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer reader.Close()
+
+    var count int64
+    for _, f := range reader.File {
+        if err := extractZipFile(f, destdir); err != nil {
+            println(err.Error())
+            return -1
+        }
+        count++
+    }
+
+    return count
+}
+
+//export ZipMerge
+func ZipMerge(output string, inputs **C.char, count C.int) int64 {
+    out, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer out.Close()
+
+    writer := zip.NewWriter(out)
+
+    var entries int64
+    for _, inputfile := range charArrayToStrings(inputs, count) {
+        reader, err := zip.OpenReader(inputfile)
+        if err != nil {
+            println(err.Error())
+            return -1
+        }
+
+        for _, f := range reader.File {
+            // OpenRaw/CreateRaw/Copy stream the entry as-is, without
+            // inflating and re-deflating already compressed data
+            rawReader, err := f.OpenRaw()
+            if err != nil {
+                reader.Close()
+                println(err.Error())
+                return -1
+            }
+
+            rawWriter, err := writer.CreateRaw(&f.FileHeader)
+            if err != nil {
+                reader.Close()
+                println(err.Error())
+                return -1
+            }
+
+            if _, err := io.Copy(rawWriter, rawReader); err != nil {
+                reader.Close()
+                println(err.Error())
+                return -1
+            }
+
+            entries++
+        }
+
+        reader.Close()
+    }
+
+    if err := writer.Close(); err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    return entries
+}
+
+func addFileToZip(writer *zip.Writer, inputfile string) error {
+    in, err := os.Open(inputfile)
+    if err != nil {
+        return err
+    }
+    defer in.Close()
+
+    info, err := in.Stat()
+    if err != nil {
+        return err
+    }
+
+    hdr, err := zip.FileInfoHeader(info)
+    if err != nil {
+        return err
+    }
+    hdr.Name = filepath.Base(inputfile)
+    hdr.Method = zip.Deflate
+
+    entry, err := writer.CreateHeader(hdr)
+    if err != nil {
+        return err
+    }
+
+    _, err = io.Copy(entry, in)
+    return err
+}
+
+func extractZipFile(f *zip.File, destdir string) error {
+    target, err := safeJoin(destdir, f.Name)
+    if err != nil {
+        return err
+    }
+
+    if f.FileInfo().IsDir() {
+        return os.MkdirAll(target, f.Mode())
+    }
+
+    if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+        return err
+    }
+
+    src, err := f.Open()
+    if err != nil {
+        return err
+    }
+    defer src.Close()
+
+    out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+    if err != nil {
+        return err
+    }
+    defer out.Close()
+
+    _, err = io.Copy(out, src)
+    return err
+}
+
+// Convert a C array of C strings into a Go string slice
+func charArrayToStrings(array **C.char, count C.int) []string {
+    slice := unsafe.Slice(array, int(count))
+    result := make([]string, int(count))
+    for i, s := range slice {
+        result[i] = C.GoString(s)
+    }
+    return result
+}
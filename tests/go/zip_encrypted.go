@@ -0,0 +1,357 @@
+package main
+
+import "C"
+
+import (
+    "archive/zip"
+    "bytes"
+    "compress/flate"
+    "crypto/aes"
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha1"
+    "encoding/binary"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+)
+
+const aeExtraFieldID = 0x9901
+
+//export ZipCreateEncrypted
+func ZipCreateEncrypted(outputfile string, password string, inputfiles **C.char, count C.int, keyBits C.int) int64 {
+    out, err := os.OpenFile(outputfile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer out.Close()
+
+    writer := zip.NewWriter(out)
+
+    for _, inputfile := range charArrayToStrings(inputfiles, count) {
+        if err := addEncryptedFileToZip(writer, inputfile, password, int(keyBits)); err != nil {
+            println(err.Error())
+            return -1
+        }
+    }
+
+    if err := writer.Close(); err != nil {
+        println(err.Error())
+        return -1
+    }
+
+    return getSize(out)
+}
+
+//export ZipExtractEncrypted
+func ZipExtractEncrypted(inputfile string, destdir string, password string) int64 {
+    reader, err := zip.OpenReader(inputfile)
+    if err != nil {
+        println(err.Error())
+        return -1
+    }
+    defer reader.Close()
+
+    var count int64
+    for _, f := range reader.File {
+        if err := extractEncryptedZipFile(f, destdir, password); err != nil {
+            println(err.Error())
+            return -1
+        }
+        count++
+    }
+
+    return count
+}
+
+// Deflate the file, encrypt it per the WinZip AE-2 scheme, and write it as a
+// raw entry so archive/zip does not try to compress or checksum it itself
+func addEncryptedFileToZip(writer *zip.Writer, inputfile string, password string, keyBits int) error {
+    in, err := os.Open(inputfile)
+    if err != nil {
+        return err
+    }
+    defer in.Close()
+
+    info, err := in.Stat()
+    if err != nil {
+        return err
+    }
+
+    plain, err := io.ReadAll(in)
+    if err != nil {
+        return err
+    }
+
+    var deflated bytes.Buffer
+    fw, err := flate.NewWriter(&deflated, flate.DefaultCompression)
+    if err != nil {
+        return err
+    }
+    if _, err := fw.Write(plain); err != nil {
+        return err
+    }
+    if err := fw.Close(); err != nil {
+        return err
+    }
+
+    keyLen, saltLen, strength, err := aesKeySizes(keyBits)
+    if err != nil {
+        return err
+    }
+
+    salt := make([]byte, saltLen)
+    if _, err := rand.Read(salt); err != nil {
+        return err
+    }
+
+    aesKey, hmacKey, verify := deriveAEKeys(password, salt, keyLen)
+
+    ciphertext, err := aesCTRCrypt(aesKey, deflated.Bytes())
+    if err != nil {
+        return err
+    }
+
+    authCode := authenticate(hmacKey, ciphertext)
+
+    var payload bytes.Buffer
+    payload.Write(salt)
+    payload.Write(verify)
+    payload.Write(ciphertext)
+    payload.Write(authCode)
+
+    hdr, err := zip.FileInfoHeader(info)
+    if err != nil {
+        return err
+    }
+    hdr.Name = filepath.Base(inputfile)
+    hdr.Method = 99
+    hdr.Flags |= 0x1
+    hdr.Extra = buildAEExtraField(strength, zip.Deflate)
+    // CRC-32 is not stored for AE-2, integrity is covered by the HMAC tag
+    hdr.CRC32 = 0
+    hdr.CompressedSize64 = uint64(payload.Len())
+    hdr.UncompressedSize64 = uint64(len(plain))
+
+    entry, err := writer.CreateRaw(hdr)
+    if err != nil {
+        return err
+    }
+
+    _, err = entry.Write(payload.Bytes())
+    return err
+}
+
+func extractEncryptedZipFile(f *zip.File, destdir string, password string) error {
+    target, err := safeJoin(destdir, f.Name)
+    if err != nil {
+        return err
+    }
+
+    if f.FileInfo().IsDir() {
+        return os.MkdirAll(target, f.Mode())
+    }
+    if f.Method != 99 {
+        return extractZipFile(f, destdir)
+    }
+
+    strength, actualMethod, ok := parseAEExtraField(f.Extra)
+    if !ok {
+        return fmt.Errorf("zip: %q: missing AE-x extra field", f.Name)
+    }
+
+    keyLen, saltLen, err := aesKeySizesFromStrength(strength)
+    if err != nil {
+        return err
+    }
+
+    rawReader, err := f.OpenRaw()
+    if err != nil {
+        return err
+    }
+
+    payload, err := io.ReadAll(rawReader)
+    if err != nil {
+        return err
+    }
+    if len(payload) < saltLen+2+10 {
+        return fmt.Errorf("zip: %q: truncated AES payload", f.Name)
+    }
+
+    salt := payload[:saltLen]
+    verify := payload[saltLen : saltLen+2]
+    authCode := payload[len(payload)-10:]
+    ciphertext := payload[saltLen+2 : len(payload)-10]
+
+    aesKey, hmacKey, wantVerify := deriveAEKeys(password, salt, keyLen)
+    if !hmac.Equal(verify, wantVerify) {
+        return fmt.Errorf("zip: %q: wrong password", f.Name)
+    }
+    if !hmac.Equal(authCode, authenticate(hmacKey, ciphertext)) {
+        return fmt.Errorf("zip: %q: authentication failed", f.Name)
+    }
+
+    plainCompressed, err := aesCTRCrypt(aesKey, ciphertext)
+    if err != nil {
+        return err
+    }
+
+    var reader io.Reader
+    switch actualMethod {
+    case zip.Store:
+        reader = bytes.NewReader(plainCompressed)
+    case zip.Deflate:
+        reader = flate.NewReader(bytes.NewReader(plainCompressed))
+    default:
+        return fmt.Errorf("zip: %q: unsupported inner compression method %d", f.Name, actualMethod)
+    }
+
+    if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+        return err
+    }
+
+    out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+    if err != nil {
+        return err
+    }
+    defer out.Close()
+
+    _, err = io.Copy(out, reader)
+    return err
+}
+
+// Derive the AES-CTR key, HMAC-SHA1 authentication key, and password
+// verification value from a password and salt, per the WinZip AE spec
+func deriveAEKeys(password string, salt []byte, keyLen int) (aesKey, hmacKey, verify []byte) {
+    material := pbkdf2HMACSHA1([]byte(password), salt, 1000, keyLen*2+2)
+    return material[:keyLen], material[keyLen : keyLen*2], material[keyLen*2:]
+}
+
+// Compute the 10-byte truncated HMAC-SHA1 authentication code over ciphertext
+func authenticate(hmacKey, ciphertext []byte) []byte {
+    mac := hmac.New(sha1.New, hmacKey)
+    mac.Write(ciphertext)
+    return mac.Sum(nil)[:10]
+}
+
+// AES-CTR with a little-endian counter starting at 1, as used by WinZip AE.
+// Since CTR mode XORs a keystream with the data, the same function both
+// encrypts and decrypts.
+func aesCTRCrypt(key, data []byte) ([]byte, error) {
+    block, err := aes.NewCipher(key)
+    if err != nil {
+        return nil, err
+    }
+
+    out := make([]byte, len(data))
+    var keystream [aes.BlockSize]byte
+    counterBlock := make([]byte, aes.BlockSize)
+
+    for offset, counter := 0, uint64(1); offset < len(data); offset, counter = offset+aes.BlockSize, counter+1 {
+        binary.LittleEndian.PutUint64(counterBlock, counter)
+        block.Encrypt(keystream[:], counterBlock)
+
+        end := offset + aes.BlockSize
+        if end > len(data) {
+            end = len(data)
+        }
+        for i := offset; i < end; i++ {
+            out[i] = data[i] ^ keystream[i-offset]
+        }
+    }
+
+    return out, nil
+}
+
+func aesKeySizes(keyBits int) (keyLen int, saltLen int, strength byte, err error) {
+    switch keyBits {
+    case 128:
+        return 16, 8, 1, nil
+    case 192:
+        return 24, 12, 2, nil
+    case 256:
+        return 32, 16, 3, nil
+    default:
+        return 0, 0, 0, fmt.Errorf("zip: unsupported AES key size: %d", keyBits)
+    }
+}
+
+func aesKeySizesFromStrength(strength byte) (keyLen int, saltLen int, err error) {
+    switch strength {
+    case 1:
+        return 16, 8, nil
+    case 2:
+        return 24, 12, nil
+    case 3:
+        return 32, 16, nil
+    default:
+        return 0, 0, fmt.Errorf("zip: unsupported AES strength value: %d", strength)
+    }
+}
+
+// Build the 0x9901 extra field identifying an AE-2 entry, its AES key
+// strength, and the compression method that was applied before encryption
+func buildAEExtraField(strength byte, actualMethod uint16) []byte {
+    buf := make([]byte, 11)
+    binary.LittleEndian.PutUint16(buf[0:2], aeExtraFieldID)
+    binary.LittleEndian.PutUint16(buf[2:4], 7)
+    binary.LittleEndian.PutUint16(buf[4:6], 2) // AE-2: CRC-32 not stored
+    buf[6] = 'A'
+    buf[7] = 'E'
+    buf[8] = strength
+    binary.LittleEndian.PutUint16(buf[9:11], actualMethod)
+    return buf
+}
+
+func parseAEExtraField(extra []byte) (strength byte, actualMethod uint16, ok bool) {
+    for len(extra) >= 4 {
+        id := binary.LittleEndian.Uint16(extra[0:2])
+        size := binary.LittleEndian.Uint16(extra[2:4])
+        if int(size) > len(extra)-4 {
+            return 0, 0, false
+        }
+        data := extra[4 : 4+size]
+        if id == aeExtraFieldID && size == 7 {
+            return data[4], binary.LittleEndian.Uint16(data[5:7]), true
+        }
+        extra = extra[4+size:]
+    }
+    return 0, 0, false
+}
+
+// PBKDF2 key derivation using HMAC-SHA1 as the pseudorandom function, per
+// RFC 8018
+func pbkdf2HMACSHA1(password, salt []byte, iterations int, keyLen int) []byte {
+    prf := hmac.New(sha1.New, password)
+    hashLen := prf.Size()
+    numBlocks := (keyLen + hashLen - 1) / hashLen
+
+    dk := make([]byte, 0, numBlocks*hashLen)
+    var blockIndex [4]byte
+
+    for block := 1; block <= numBlocks; block++ {
+        prf.Reset()
+        prf.Write(salt)
+        binary.BigEndian.PutUint32(blockIndex[:], uint32(block))
+        prf.Write(blockIndex[:])
+        u := prf.Sum(nil)
+
+        t := make([]byte, len(u))
+        copy(t, u)
+
+        for i := 1; i < iterations; i++ {
+            prf.Reset()
+            prf.Write(u)
+            u = prf.Sum(nil)
+            for j := range t {
+                t[j] ^= u[j]
+            }
+        }
+
+        dk = append(dk, t...)
+    }
+
+    return dk[:keyLen]
+}